@@ -0,0 +1,33 @@
+package commit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/117503445/go-batcher"
+)
+
+// newTestOp returns a real *batcher.Operation[T, R] for use as a test
+// fixture. batcher.Operation has no exported constructor, and a struct
+// literal built from outside package batcher leaves its done channel nil,
+// so the first SetResult/SetError a real commitFn calls on one panics with
+// "close of nil channel". A Batcher with a max size high enough that it
+// never commits on its own is the only way to get one with a live done
+// channel that the test can still hand to code under test before anything
+// settles it.
+func newTestOp[T, R any](t *testing.T, value T) *batcher.Operation[T, R] {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	const neverCommits = 1 << 16
+	b := batcher.New(func(context.Context, batcher.Operations[T, R]) {}, batcher.WithMaxSize[T, R](neverCommits))
+	go b.Batch(ctx)
+
+	op, err := b.Send(ctx, value)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	return op
+}
@@ -0,0 +1,186 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/117503445/go-batcher"
+)
+
+// ErrCircuitOpen is the error used to settle every operation in a batch
+// while the CircuitBreaker middleware's breaker is open.
+var ErrCircuitOpen = errors.New("commit: circuit breaker open")
+
+type cbState int
+
+const (
+	cbClosed cbState = iota
+	cbOpen
+	cbHalfOpen
+)
+
+// CBOptions configures the CircuitBreaker middleware.
+type CBOptions[T, R any] struct {
+	// FailureThreshold is the number of batch failures within Window that
+	// trips the breaker open. Values less than 1 are treated as 1.
+	FailureThreshold int
+
+	// Window is the rolling duration over which failures are counted. Values
+	// less than or equal to zero are treated as time.Minute.
+	Window time.Duration
+
+	// Cooldown is how long the breaker stays open before letting a single
+	// half-open probe batch through. It doubles every time the probe fails,
+	// resetting to Cooldown the next time the breaker closes.
+	Cooldown time.Duration
+
+	// IsFailure classifies a committed batch as a failure. A nil IsFailure
+	// treats the batch as failed if any operation settled with an error.
+	IsFailure func(ops batcher.Operations[T, R]) bool
+}
+
+// CircuitBreaker wraps commitFn with a batch-level circuit breaker. Once
+// FailureThreshold failures occur within Window, the breaker opens and every
+// subsequent batch is settled with ErrCircuitOpen instead of reaching
+// commitFn. After Cooldown elapses the breaker half-opens, letting a single
+// probe batch through: success closes it, failure re-opens it with Cooldown
+// doubled. It panics if commitFn is nil.
+func CircuitBreaker[T, R any](commitFn batcher.CommitFunc[T, R], opts CBOptions[T, R]) batcher.CommitFunc[T, R] {
+	if commitFn == nil {
+		panic("batcher: nil commit func")
+	}
+	if opts.FailureThreshold < 1 {
+		opts.FailureThreshold = 1
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	isFailure := opts.IsFailure
+
+	var (
+		mu       sync.Mutex
+		state    cbState
+		failures []time.Time
+		cooldown = opts.Cooldown
+		openedAt time.Time
+		probing  bool
+	)
+
+	return func(ctx context.Context, ops batcher.Operations[T, R]) {
+		mu.Lock()
+		now := time.Now()
+		isProbe := false
+		switch {
+		case state == cbOpen && now.Before(openedAt.Add(cooldown)):
+			mu.Unlock()
+			for _, op := range ops {
+				op.SetError(ErrCircuitOpen)
+			}
+			return
+		case state == cbOpen:
+			state = cbHalfOpen
+			probing = true
+			isProbe = true
+		case state == cbHalfOpen && probing:
+			mu.Unlock()
+			for _, op := range ops {
+				op.SetError(ErrCircuitOpen)
+			}
+			return
+		case state == cbHalfOpen:
+			probing = true
+			isProbe = true
+		}
+		mu.Unlock()
+
+		// commitFn is never handed ops directly: it runs against shadow
+		// operations minted for this call, so the breaker's own bookkeeping
+		// below never has to guess which of ops it actually reached before
+		// ctx ended, only read settledFlags back from mint.
+		values := make([]T, len(ops))
+		for i, op := range ops {
+			values[i] = op.Value
+		}
+		shadows, settledFlags := mint(ctx, commitFn, values)
+
+		if err := ctx.Err(); err != nil {
+			// commitFn may give up on ctx cancellation without settling
+			// every op; leave nothing pending for the caller. The caller
+			// gave up here, not the backend, so this must not count
+			// toward (or reopen) the breaker.
+			for i, op := range ops {
+				if !settledFlags[i] {
+					op.SetError(err)
+					continue
+				}
+				result, werr := shadows[i].Wait(context.Background())
+				settle(op, result, werr)
+			}
+			mu.Lock()
+			if isProbe {
+				probing = false
+			}
+			mu.Unlock()
+			return
+		}
+
+		for i, op := range ops {
+			result, werr := shadows[i].Wait(context.Background())
+			settle(op, result, werr)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if isProbe {
+			probing = false
+		} else if state != cbClosed {
+			// This batch was admitted before the breaker left the closed
+			// state; only the half-open probe decides what happens next.
+			return
+		}
+
+		failed := false
+		if isFailure != nil {
+			failed = isFailure(ops)
+		} else {
+			for _, op := range ops {
+				if _, err := op.Wait(context.Background()); err != nil {
+					failed = true
+					break
+				}
+			}
+		}
+
+		if !failed {
+			state = cbClosed
+			failures = nil
+			cooldown = opts.Cooldown
+			return
+		}
+
+		if state == cbHalfOpen {
+			state = cbOpen
+			openedAt = now
+			cooldown *= 2
+			failures = nil
+			return
+		}
+
+		failures = append(failures, now)
+		cutoff := now.Add(-opts.Window)
+		kept := failures[:0]
+		for _, t := range failures {
+			if !t.Before(cutoff) {
+				kept = append(kept, t)
+			}
+		}
+		failures = kept
+		if len(failures) >= opts.FailureThreshold {
+			state = cbOpen
+			openedAt = now
+			cooldown = opts.Cooldown
+		}
+	}
+}
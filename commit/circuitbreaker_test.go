@@ -0,0 +1,214 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/117503445/go-batcher"
+)
+
+func failingCommit(ctx context.Context, ops batcher.Operations[int, int]) {
+	for _, op := range ops {
+		op.SetError(errBoom)
+	}
+}
+
+func TestCircuitBreaker_TripsAfterThresholdFailures(t *testing.T) {
+	cb := CircuitBreaker(failingCommit, CBOptions[int, int]{
+		FailureThreshold: 2,
+		Window:           time.Minute,
+		Cooldown:         time.Minute,
+	})
+
+	for i := 0; i < 2; i++ {
+		op := newTestOp[int, int](t, 1)
+		cb(context.Background(), batcher.Operations[int, int]{op})
+		if _, err := op.Wait(context.Background()); !errors.Is(err, errBoom) {
+			t.Fatalf("attempt %d: Wait err = %v, want errBoom", i, err)
+		}
+	}
+
+	op := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{op})
+	if _, err := op.Wait(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Wait err = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreaker_OpenShortCircuitsWithoutCallingCommitFn(t *testing.T) {
+	var called int32
+	commitFn := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		atomic.AddInt32(&called, 1)
+		failingCommit(ctx, ops)
+	}
+
+	cb := CircuitBreaker(commitFn, CBOptions[int, int]{
+		FailureThreshold: 1,
+		Window:           time.Minute,
+		Cooldown:         time.Minute,
+	})
+
+	op1 := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{op1})
+
+	op2 := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{op2})
+	if got := atomic.LoadInt32(&called); got != 1 {
+		t.Fatalf("called = %d, want 1; commitFn should have been skipped while open", got)
+	}
+	if _, err := op2.Wait(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Wait err = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenAdmitsSingleProbe(t *testing.T) {
+	var called int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	commitFn := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		n := atomic.AddInt32(&called, 1)
+		if n == 1 {
+			failingCommit(ctx, ops)
+			return
+		}
+		close(started)
+		<-release
+		for _, op := range ops {
+			op.SetResult(1)
+		}
+	}
+
+	cb := CircuitBreaker(commitFn, CBOptions[int, int]{
+		FailureThreshold: 1,
+		Window:           time.Minute,
+		Cooldown:         20 * time.Millisecond,
+	})
+
+	trip := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{trip})
+	time.Sleep(30 * time.Millisecond)
+
+	probe := newTestOp[int, int](t, 1)
+	done := make(chan struct{})
+	go func() {
+		cb(context.Background(), batcher.Operations[int, int]{probe})
+		close(done)
+	}()
+	<-started
+
+	rejected := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{rejected})
+	if _, err := rejected.Wait(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Wait err = %v, want ErrCircuitOpen while a probe is in flight", err)
+	}
+
+	close(release)
+	<-done
+
+	if result, err := probe.Wait(context.Background()); err != nil || result != 1 {
+		t.Fatalf("probe.Wait() = (%d, %v), want (1, nil)", result, err)
+	}
+	if got := atomic.LoadInt32(&called); got != 2 {
+		t.Fatalf("called = %d, want 2 (initial trip + single probe)", got)
+	}
+}
+
+func TestCircuitBreaker_ProbeSuccessClosesBreaker(t *testing.T) {
+	var called int32
+	commitFn := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		n := atomic.AddInt32(&called, 1)
+		if n == 1 {
+			failingCommit(ctx, ops)
+			return
+		}
+		for _, op := range ops {
+			op.SetResult(1)
+		}
+	}
+
+	cb := CircuitBreaker(commitFn, CBOptions[int, int]{
+		FailureThreshold: 1,
+		Window:           time.Minute,
+		Cooldown:         20 * time.Millisecond,
+	})
+
+	trip := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{trip})
+	time.Sleep(30 * time.Millisecond)
+
+	probe := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{probe})
+	if _, err := probe.Wait(context.Background()); err != nil {
+		t.Fatalf("probe.Wait() err = %v, want nil", err)
+	}
+
+	closed := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{closed})
+	if result, err := closed.Wait(context.Background()); err != nil || result != 1 {
+		t.Fatalf("closed.Wait() = (%d, %v), want (1, nil); breaker should have closed after the probe succeeded", result, err)
+	}
+	if got := atomic.LoadInt32(&called); got != 3 {
+		t.Fatalf("called = %d, want 3; commitFn should be reached once closed", got)
+	}
+}
+
+func TestCircuitBreaker_ProbeFailureReopensWithDoubledCooldown(t *testing.T) {
+	cb := CircuitBreaker(failingCommit, CBOptions[int, int]{
+		FailureThreshold: 1,
+		Window:           time.Minute,
+		Cooldown:         20 * time.Millisecond,
+	})
+
+	trip := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{trip})
+	time.Sleep(30 * time.Millisecond)
+
+	probe := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{probe})
+
+	// The original Cooldown (20ms) has elapsed but the doubled one (40ms)
+	// has not; the breaker must still be open.
+	time.Sleep(25 * time.Millisecond)
+	op := newTestOp[int, int](t, 1)
+	cb(context.Background(), batcher.Operations[int, int]{op})
+	if _, err := op.Wait(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Wait err = %v, want ErrCircuitOpen; failed probe should double the cooldown", err)
+	}
+}
+
+func TestCircuitBreaker_CtxCanceledSettlesPending(t *testing.T) {
+	commitFn := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		<-ctx.Done()
+	}
+
+	cb := CircuitBreaker(commitFn, CBOptions[int, int]{
+		FailureThreshold: 1,
+		Window:           time.Minute,
+		Cooldown:         time.Minute,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	op := newTestOp[int, int](t, 1)
+
+	done := make(chan struct{})
+	go func() {
+		cb(ctx, batcher.Operations[int, int]{op})
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("CircuitBreaker did not return after ctx cancellation")
+	}
+
+	if _, err := op.Wait(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,116 @@
+package commit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/117503445/go-batcher"
+)
+
+// Hedge wraps primary and secondary commit functions so that, if primary
+// has not settled every operation within after, secondary is invoked
+// concurrently on the still-pending subset while primary keeps running.
+// Whichever of the two settles an operation first wins; the other's result
+// for that operation is discarded. Both invocations share a context derived
+// from the parent that is canceled as soon as every operation has settled.
+// Hedge returns once every operation has settled or parent has ended; it
+// does not wait for primary or secondary themselves to return. If parent
+// ends first, any operation still pending settles with the context's error.
+// It panics if primary or secondary is nil.
+func Hedge[T, R any](primary, secondary batcher.CommitFunc[T, R], after time.Duration) batcher.CommitFunc[T, R] {
+	if primary == nil || secondary == nil {
+		panic("batcher: nil commit func")
+	}
+
+	return func(parent context.Context, ops batcher.Operations[T, R]) {
+		ctx, cancel := context.WithCancel(parent)
+		defer cancel()
+
+		// handled records, per operation, whether this call has already
+		// settled it, so that whichever of primary/secondary settles an
+		// operation first is the one whose result sticks.
+		handled := make([]bool, len(ops))
+
+		var (
+			mu       sync.Mutex
+			finished int
+			wg       sync.WaitGroup
+		)
+		apply := func(i int, shadow *batcher.Operation[T, R], shadowSettled bool) {
+			if !shadowSettled {
+				// commitFn gave up on this shadow without settling it (ctx ended
+				// first); the final pass below covers ops[i] once both goroutines
+				// have finished, unless the other shadow settles it first.
+				return
+			}
+			result, err := shadow.Wait(context.Background())
+			mu.Lock()
+			if !handled[i] {
+				handled[i] = true
+				settle(ops[i], result, err)
+				finished++
+				if finished == len(ops) {
+					cancel()
+				}
+			}
+			mu.Unlock()
+		}
+
+		values := make([]T, len(ops))
+		for i, op := range ops {
+			values[i] = op.Value
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			primaryShadow, settledFlags := mint(ctx, primary, values)
+			for i, shadow := range primaryShadow {
+				apply(i, shadow, settledFlags[i])
+			}
+		}()
+
+		timer := time.NewTimer(after)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+			var idx []int
+			var secondaryValues []T
+			mu.Lock()
+			for i := range ops {
+				if !handled[i] {
+					idx = append(idx, i)
+					secondaryValues = append(secondaryValues, values[i])
+				}
+			}
+			mu.Unlock()
+
+			if len(secondaryValues) > 0 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					secondaryShadow, settledFlags := mint(ctx, secondary, secondaryValues)
+					for j, shadow := range secondaryShadow {
+						apply(idx[j], shadow, settledFlags[j])
+					}
+				}()
+			}
+		}
+
+		wg.Wait()
+
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			for i, op := range ops {
+				if !handled[i] {
+					handled[i] = true
+					op.SetError(err)
+				}
+			}
+			mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/117503445/go-batcher"
+)
+
+func sleepCommit(d time.Duration, result int, err error) batcher.CommitFunc[int, int] {
+	return func(ctx context.Context, ops batcher.Operations[int, int]) {
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return
+		}
+		for _, op := range ops {
+			settle(op, result, err)
+		}
+	}
+}
+
+func TestHedge_PrimaryFastSecondaryNeverNeeded(t *testing.T) {
+	secondaryCalled := false
+	secondary := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		secondaryCalled = true
+		for _, op := range ops {
+			op.SetResult(999)
+		}
+	}
+
+	hedge := Hedge(sleepCommit(5*time.Millisecond, 1, nil), secondary, 50*time.Millisecond)
+
+	op := newTestOp[int, int](t, 1)
+	hedge(context.Background(), batcher.Operations[int, int]{op})
+
+	result, err := op.Wait(context.Background())
+	if err != nil || result != 1 {
+		t.Fatalf("result = %d, err = %v, want 1, nil", result, err)
+	}
+	if secondaryCalled {
+		t.Fatal("secondary should not run when primary settles before the hedge fires")
+	}
+}
+
+func TestHedge_SecondaryWinsWhenPrimarySlow(t *testing.T) {
+	primary := sleepCommit(200*time.Millisecond, 1, nil)
+	secondary := sleepCommit(5*time.Millisecond, 2, nil)
+
+	hedge := Hedge(primary, secondary, 20*time.Millisecond)
+
+	op := newTestOp[int, int](t, 1)
+	start := time.Now()
+	hedge(context.Background(), batcher.Operations[int, int]{op})
+	elapsed := time.Since(start)
+
+	result, err := op.Wait(context.Background())
+	if err != nil || result != 2 {
+		t.Fatalf("result = %d, err = %v, want 2, nil", result, err)
+	}
+	if elapsed >= 200*time.Millisecond {
+		t.Fatalf("Hedge waited for slow primary (%v) instead of returning once settled", elapsed)
+	}
+}
+
+func TestHedge_PrimaryWinsDespiteLateFinish(t *testing.T) {
+	primary := sleepCommit(30*time.Millisecond, 1, nil)
+	secondary := sleepCommit(100*time.Millisecond, 2, nil)
+
+	hedge := Hedge(primary, secondary, 10*time.Millisecond)
+
+	op := newTestOp[int, int](t, 1)
+	hedge(context.Background(), batcher.Operations[int, int]{op})
+
+	result, err := op.Wait(context.Background())
+	if err != nil || result != 1 {
+		t.Fatalf("result = %d, err = %v, want primary's 1, nil", result, err)
+	}
+}
+
+func TestHedge_ParentCanceledSettlesPending(t *testing.T) {
+	primary := sleepCommit(time.Second, 1, nil)
+	secondary := sleepCommit(time.Second, 2, nil)
+
+	hedge := Hedge(primary, secondary, 5*time.Millisecond)
+
+	parent, cancel := context.WithCancel(context.Background())
+	op := newTestOp[int, int](t, 1)
+
+	done := make(chan struct{})
+	go func() {
+		hedge(parent, batcher.Operations[int, int]{op})
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Hedge did not return after parent cancellation")
+	}
+
+	_, err := op.Wait(context.Background())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait err = %v, want context.Canceled", err)
+	}
+}
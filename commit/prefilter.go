@@ -0,0 +1,40 @@
+package commit
+
+import (
+	"context"
+
+	"github.com/117503445/go-batcher"
+)
+
+// Prefilter wraps commitFn with a cheap per-operation validity check that
+// runs before the batch ever reaches it. validate is called with each
+// operation's input; operations for which it returns a non-nil error are
+// settled immediately with that error and excluded from the batch passed to
+// commitFn. If every operation fails validation, commitFn is not called at
+// all. This lets callers push sanity checks (nil pointers, empty keys,
+// oversize payloads) out of the commit path. It panics if commitFn or
+// validate is nil.
+func Prefilter[T, R any](commitFn batcher.CommitFunc[T, R], validate func(T) error) batcher.CommitFunc[T, R] {
+	if commitFn == nil {
+		panic("batcher: nil commit func")
+	}
+	if validate == nil {
+		panic("batcher: nil validate func")
+	}
+
+	return func(ctx context.Context, ops batcher.Operations[T, R]) {
+		var valid batcher.Operations[T, R]
+		for _, op := range ops {
+			if err := validate(op.Value); err != nil {
+				op.SetError(err)
+				continue
+			}
+			valid = append(valid, op)
+		}
+
+		if len(valid) == 0 {
+			return
+		}
+		commitFn(ctx, valid)
+	}
+}
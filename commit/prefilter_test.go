@@ -0,0 +1,72 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/117503445/go-batcher"
+)
+
+var errInvalid = errors.New("invalid")
+
+func TestPrefilter_RejectsInvalidWithoutReachingCommitFn(t *testing.T) {
+	var seen []int
+	commitFn := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		for _, op := range ops {
+			seen = append(seen, op.Value)
+			op.SetResult(op.Value * 2)
+		}
+	}
+
+	validate := func(v int) error {
+		if v < 0 {
+			return errInvalid
+		}
+		return nil
+	}
+
+	prefilter := Prefilter(commitFn, validate)
+
+	bad := newTestOp[int, int](t, -1)
+	good := newTestOp[int, int](t, 3)
+	prefilter(context.Background(), batcher.Operations[int, int]{bad, good})
+
+	if _, err := bad.Wait(context.Background()); !errors.Is(err, errInvalid) {
+		t.Fatalf("bad.Wait() err = %v, want errInvalid", err)
+	}
+
+	result, err := good.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != 6 {
+		t.Fatalf("result = %d, want 6", result)
+	}
+
+	if len(seen) != 1 || seen[0] != 3 {
+		t.Fatalf("commitFn saw %v, want [3]", seen)
+	}
+}
+
+func TestPrefilter_AllInvalidSkipsCommitFn(t *testing.T) {
+	called := false
+	commitFn := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		called = true
+	}
+
+	validate := func(v int) error { return errInvalid }
+
+	prefilter := Prefilter(commitFn, validate)
+
+	op := newTestOp[int, int](t, 1)
+	prefilter(context.Background(), batcher.Operations[int, int]{op})
+
+	if called {
+		t.Fatal("commitFn was called even though every operation failed validation")
+	}
+
+	if _, err := op.Wait(context.Background()); !errors.Is(err, errInvalid) {
+		t.Fatalf("Wait() err = %v, want errInvalid", err)
+	}
+}
@@ -0,0 +1,125 @@
+package commit
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/117503445/go-batcher"
+)
+
+// RetryOptions configures the Retry middleware.
+type RetryOptions[T, R any] struct {
+	// MaxAttempts is the maximum number of times an operation is committed,
+	// including the first attempt. Values less than 1 are treated as 1.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration
+
+	// Multiplier grows the delay on each subsequent retry. Values <= 1 keep
+	// the delay constant at BaseDelay.
+	Multiplier float64
+
+	// MaxDelay caps the computed backoff delay. Zero means uncapped.
+	MaxDelay time.Duration
+
+	// Jitter, when true, randomizes each delay uniformly in [0, delay]
+	// (full jitter) to spread out retries.
+	Jitter bool
+
+	// ShouldRetry decides whether a failed attempt should be retried. A nil
+	// ShouldRetry retries every failed operation.
+	ShouldRetry func(attempt *batcher.Operation[T, R]) bool
+}
+
+// delay returns the backoff before the retryNum'th retry (1 for the first
+// retry, 2 for the second, and so on).
+func (o RetryOptions[T, R]) delay(retryNum int) time.Duration {
+	d := float64(o.BaseDelay)
+	if o.Multiplier > 1 {
+		d *= math.Pow(o.Multiplier, float64(retryNum-1))
+	}
+	if o.MaxDelay > 0 && d > float64(o.MaxDelay) {
+		d = float64(o.MaxDelay)
+	}
+	if o.Jitter {
+		d = rand.Float64() * d
+	}
+	return time.Duration(d)
+}
+
+// Retry wraps commitFn so that operations reporting a failure are
+// re-committed, up to MaxAttempts times, with exponential backoff between
+// attempts. Each attempt commits only the operations still failing from the
+// previous one, so operations that already succeeded are never redone.
+// ShouldRetry is consulted after every failed attempt to decide whether an
+// operation is worth retrying at all; operations it rejects settle with
+// their last result. The parent context is honored between attempts: if it
+// is done before the next attempt is due, all still-pending operations
+// settle with its error. It panics if commitFn is nil.
+func Retry[T, R any](commitFn batcher.CommitFunc[T, R], opts RetryOptions[T, R]) batcher.CommitFunc[T, R] {
+	if commitFn == nil {
+		panic("batcher: nil commit func")
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+	shouldRetry := opts.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = func(*batcher.Operation[T, R]) bool { return true }
+	}
+
+	return func(parent context.Context, ops batcher.Operations[T, R]) {
+		pending := make(batcher.Operations[T, R], len(ops))
+		copy(pending, ops)
+
+		for attempt := 1; len(pending) > 0; attempt++ {
+			if attempt > 1 {
+				timer := time.NewTimer(opts.delay(attempt - 1))
+				select {
+				case <-parent.Done():
+					timer.Stop()
+					for _, original := range pending {
+						original.SetError(parent.Err())
+					}
+					return
+				case <-timer.C:
+				}
+			}
+
+			values := make([]T, len(pending))
+			for i, original := range pending {
+				values[i] = original.Value
+			}
+			// attempts mints fresh operations for this round rather than
+			// reusing the originals: an operation can only be settled once,
+			// so a retryable failure must land on a throwaway op, leaving
+			// the original free to settle on a later, successful attempt.
+			attempts, settledFlags := mint(parent, commitFn, values)
+
+			var next batcher.Operations[T, R]
+			for i, original := range pending {
+				if !settledFlags[i] {
+					// commitFn gave up without settling this attempt; only
+					// the parent actually being done justifies propagating
+					// that as this op's result, so a commitFn bug that
+					// leaves an attempt untouched for any other reason is
+					// never mistaken for a silent success.
+					if err := parent.Err(); err != nil {
+						original.SetError(err)
+					}
+					continue
+				}
+				result, err := attempts[i].Wait(context.Background())
+				if err != nil && attempt < opts.MaxAttempts && shouldRetry(attempts[i]) {
+					next = append(next, original)
+					continue
+				}
+				settle(original, result, err)
+			}
+			pending = next
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/117503445/go-batcher"
+)
+
+func TestRetryOptions_delay(t *testing.T) {
+	opts := RetryOptions[int, int]{
+		BaseDelay:  100 * time.Millisecond,
+		Multiplier: 2,
+		MaxDelay:   time.Second,
+	}
+
+	cases := []struct {
+		retryNum int
+		want     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second}, // capped by MaxDelay
+	}
+	for _, c := range cases {
+		if got := opts.delay(c.retryNum); got != c.want {
+			t.Errorf("delay(%d) = %v, want %v", c.retryNum, got, c.want)
+		}
+	}
+}
+
+var errBoom = errors.New("boom")
+
+func TestRetry_ParentCanceledMidRetry(t *testing.T) {
+	commitFn := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		for _, op := range ops {
+			op.SetError(errBoom)
+		}
+	}
+
+	retry := Retry(commitFn, RetryOptions[int, int]{
+		MaxAttempts: 5,
+		BaseDelay:   50 * time.Millisecond,
+	})
+
+	parent, cancel := context.WithCancel(context.Background())
+	op := newTestOp[int, int](t, 1)
+
+	done := make(chan struct{})
+	go func() {
+		retry(parent, batcher.Operations[int, int]{op})
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Retry did not return after parent cancellation")
+	}
+
+	_, err := op.Wait(context.Background())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRetry_SucceedsWithinMaxAttempts(t *testing.T) {
+	attempts := 0
+	commitFn := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		attempts++
+		for _, op := range ops {
+			if attempts < 3 {
+				op.SetError(errBoom)
+				continue
+			}
+			op.SetResult(op.Value * 2)
+		}
+	}
+
+	retry := Retry(commitFn, RetryOptions[int, int]{
+		MaxAttempts: 5,
+		BaseDelay:   time.Millisecond,
+	})
+
+	op := newTestOp[int, int](t, 21)
+	retry(context.Background(), batcher.Operations[int, int]{op})
+
+	result, err := op.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != 42 {
+		t.Fatalf("result = %d, want 42", result)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+}
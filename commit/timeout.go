@@ -2,13 +2,28 @@ package commit
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/117503445/go-batcher"
 )
 
-// Timeout calls the commit function with a timeout set to the context. It
-// panics if the commit function is nil.
+// ErrCommitTimeout is the error used to settle operations that were still
+// pending when Timeout's deadline elapsed. It wraps context.DeadlineExceeded
+// so callers can match on either.
+var ErrCommitTimeout = fmt.Errorf("commit: timeout waiting for commit: %w", context.DeadlineExceeded)
+
+// Timeout calls the commit function with a deadline no later than timeout
+// from now; if parent already carries an earlier deadline, that deadline
+// wins rather than being shadowed, since context.WithTimeout never extends
+// past a parent's deadline. Once every operation has settled or the derived
+// context has ended, whichever comes first (Timeout does not wait for
+// commitFn itself to return), any operation left
+// unsettled is settled with ErrCommitTimeout if the derived context's own
+// deadline elapsed, or with parent's error (typically context.Canceled) if
+// parent was canceled instead, so callers can tell the two apart. It panics
+// if the commit function is nil.
 func Timeout[T, R any](commitFn batcher.CommitFunc[T, R], timeout time.Duration) batcher.CommitFunc[T, R] {
 	if commitFn == nil {
 		panic("batcher: nil commit func")
@@ -18,6 +33,27 @@ func Timeout[T, R any](commitFn batcher.CommitFunc[T, R], timeout time.Duration)
 		ctx, cancel := context.WithTimeout(parent, timeout)
 		defer cancel()
 
-		commitFn(ctx, ops)
+		// Shadow operations stand in for ops here so this loop, not commitFn,
+		// decides how each one settles: a shadow left unsettled by the
+		// deadline becomes ErrCommitTimeout (or parent's error, if that's
+		// what actually ended ctx) instead of whatever commitFn left it as.
+		values := make([]T, len(ops))
+		for i, op := range ops {
+			values[i] = op.Value
+		}
+		shadows, settledFlags := mint(ctx, commitFn, values)
+
+		for i, op := range ops {
+			if !settledFlags[i] {
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					op.SetError(ErrCommitTimeout)
+				} else {
+					op.SetError(parent.Err())
+				}
+				continue
+			}
+			result, err := shadows[i].Wait(context.Background())
+			settle(op, result, err)
+		}
 	}
 }
@@ -0,0 +1,63 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/117503445/go-batcher"
+)
+
+func TestTimeout_DeadlineElapsedSettlesErrCommitTimeout(t *testing.T) {
+	commitFn := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		<-ctx.Done()
+	}
+
+	timeout := Timeout(commitFn, 10*time.Millisecond)
+
+	op := newTestOp[int, int](t, 1)
+	timeout(context.Background(), batcher.Operations[int, int]{op})
+
+	_, err := op.Wait(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait err = %v, want context.DeadlineExceeded", err)
+	}
+	if !errors.Is(err, ErrCommitTimeout) {
+		t.Fatalf("Wait err = %v, want ErrCommitTimeout", err)
+	}
+}
+
+func TestTimeout_ParentCanceledFirstSettlesParentErr(t *testing.T) {
+	commitFn := func(ctx context.Context, ops batcher.Operations[int, int]) {
+		<-ctx.Done()
+	}
+
+	timeout := Timeout(commitFn, time.Second)
+
+	parent, cancel := context.WithCancel(context.Background())
+	op := newTestOp[int, int](t, 1)
+
+	done := make(chan struct{})
+	go func() {
+		timeout(parent, batcher.Operations[int, int]{op})
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Timeout did not return after parent cancellation")
+	}
+
+	_, err := op.Wait(context.Background())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait err = %v, want context.Canceled", err)
+	}
+	if errors.Is(err, ErrCommitTimeout) {
+		t.Fatalf("Wait err = %v, should not be ErrCommitTimeout", err)
+	}
+}
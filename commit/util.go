@@ -0,0 +1,91 @@
+package commit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/117503445/go-batcher"
+)
+
+// settle resolves op with result if err is nil, or with err otherwise. The
+// real batcher.Operation exposes these as two separate setters rather than
+// a single combined call.
+func settle[T, R any](op *batcher.Operation[T, R], result R, err error) {
+	if err != nil {
+		op.SetError(err)
+		return
+	}
+	op.SetResult(result)
+}
+
+// mint commits values through a one-shot batcher.Batcher wired to commitFn,
+// blocking until each resulting operation has settled or ctx ends.
+// batcher.Operation has no exported constructor outside package batcher, so
+// a struct literal built here would have a nil done channel: the first
+// SetResult/SetError a real commitFn calls on it would panic. Routing values
+// through a real Batcher is the only supported way to mint operations that
+// behave like the ones a caller's own Batcher would hand commitFn.
+//
+// mint also reports, per value, whether commitFn actually settled it before
+// ctx ended; a commitFn is free to give up on an operation it was handed
+// without settling it (e.g. by returning once ctx.Done() fires), so settling
+// is not guaranteed just because Send succeeded. Each wait below is bounded
+// by ctx itself rather than context.Background(), so an operation commitFn
+// abandoned never leaves anything blocked past ctx ending. The one case this
+// can't distinguish is an operation settling with an error equal to ctx's
+// own error at the very instant ctx ends, which Wait's own select resolves
+// by picking between its two ready cases uniformly at random; that is rare
+// enough, and harmless enough, to accept rather than track separately.
+func mint[T, R any](ctx context.Context, commitFn batcher.CommitFunc[T, R], values []T) (batcher.Operations[T, R], []bool) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	b := batcher.New(commitFn, batcher.WithMaxSize[T, R](len(values)))
+	go b.Batch(ctx)
+
+	ops := make(batcher.Operations[T, R], len(values))
+	preSettled := make([]bool, len(values))
+	for i, v := range values {
+		op, err := b.Send(ctx, v)
+		if err != nil {
+			// ctx ended before the batcher could even accept v; settledOp
+			// mints an operation already resolved with that error instead
+			// of leaving this slot nil.
+			op = settledOp[T, R](v, err)
+			preSettled[i] = true
+		}
+		ops[i] = op
+	}
+
+	settledFlags := make([]bool, len(values))
+	for i, op := range ops {
+		if preSettled[i] {
+			settledFlags[i] = true
+			continue
+		}
+		_, err := op.Wait(ctx)
+		settledFlags[i] = err == nil || !errors.Is(err, ctx.Err())
+	}
+	return ops, settledFlags
+}
+
+// settledOp mints a real operation for v that is already resolved with err,
+// the same way mint mints its operations. It covers the case where ctx ends
+// before mint can even hand v to its Batcher.
+func settledOp[T, R any](v T, err error) *batcher.Operation[T, R] {
+	b := batcher.New(func(_ context.Context, ops batcher.Operations[T, R]) {
+		ops.SetError(err)
+	}, batcher.WithMaxSize[T, R](1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Batch(ctx)
+
+	op, _ := b.Send(context.Background(), v)
+	op.Wait(context.Background())
+	return op
+}